@@ -0,0 +1,52 @@
+package parsesyslog
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+)
+
+// Framing extracts exactly one framed syslog message from a stream, per
+// RFC6587, and returns a reader bounded to that message's bytes
+// See: https://datatracker.ietf.org/doc/html/rfc6587#section-3.4
+type Framing interface {
+	// Frame consumes one framed message from r and returns a reader
+	// limited to its bytes, with any framing overhead already stripped
+	Frame(r *bufio.Reader) (io.Reader, error)
+}
+
+// OctetCountingFraming implements RFC6587 §3.4.1 octet-counting framing,
+// where each message is preceded by its length as an ASCII decimal number
+// followed by a single space
+type OctetCountingFraming struct{}
+
+// Frame satisfies the Framing interface
+func (OctetCountingFraming) Frame(r *bufio.Reader) (io.Reader, error) {
+	ml, err := readMsgLength(r)
+	if err != nil {
+		return nil, err
+	}
+	return io.LimitReader(r, int64(ml)), nil
+}
+
+// NonTransparentFraming implements RFC6587 §3.4.2 non-transparent framing,
+// where messages are separated by a trailer byte instead of being prefixed
+// with their length. Delim defaults to '\n' when left at the zero value.
+// Since '\x00' is that zero value, a NUL trailer cannot be selected: setting
+// Delim to '\x00' also frames on '\n'
+type NonTransparentFraming struct {
+	Delim byte
+}
+
+// Frame satisfies the Framing interface
+func (f NonTransparentFraming) Frame(r *bufio.Reader) (io.Reader, error) {
+	delim := f.Delim
+	if delim == 0 {
+		delim = '\n'
+	}
+	line, err := r.ReadBytes(delim)
+	if err != nil {
+		return nil, err
+	}
+	return bytes.NewReader(line[:len(line)-1]), nil
+}
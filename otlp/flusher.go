@@ -0,0 +1,150 @@
+package otlp
+
+import (
+	"context"
+	"sync"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+
+	collectorpb "go.opentelemetry.io/proto/otlp/collector/logs/v1"
+	logspb "go.opentelemetry.io/proto/otlp/logs/v1"
+	resourcepb "go.opentelemetry.io/proto/otlp/resource/v1"
+)
+
+// defaultBatchSize is the number of records a Flusher buffers before it
+// exports automatically
+const defaultBatchSize = 512
+
+// Compression selects the wire compression used when a Flusher ships a
+// batch to its OTLP endpoint. The gzip and zstd compressors must be
+// registered with grpc's encoding package beforehand, e.g. by blank
+// importing "google.golang.org/grpc/encoding/gzip"
+type Compression string
+
+const (
+	// CompressionNone sends batches uncompressed
+	CompressionNone Compression = ""
+	// CompressionGzip compresses batches with gzip
+	CompressionGzip Compression = "gzip"
+	// CompressionZstd compresses batches with zstd
+	CompressionZstd Compression = "zstd"
+)
+
+// FlusherOption configures a Flusher
+type FlusherOption func(*Flusher)
+
+// WithBatchSize overrides the number of records buffered before a Flusher
+// exports them automatically
+func WithBatchSize(n int) FlusherOption {
+	return func(f *Flusher) { f.batchSize = n }
+}
+
+// WithCompression selects the wire compression used for exported batches
+func WithCompression(c Compression) FlusherOption {
+	return func(f *Flusher) { f.compression = c }
+}
+
+// Flusher batches LogRecords produced by Convert and ships them to an
+// OTLP/gRPC endpoint whenever a batch fills up or Flush or Close is called;
+// there is no time-based flushing
+type Flusher struct {
+	conn        *grpc.ClientConn
+	client      collectorpb.LogsServiceClient
+	resource    *resourcepb.Resource
+	batchSize   int
+	compression Compression
+	dialOpts    []grpc.DialOption
+
+	mu      sync.Mutex
+	records []*logspb.LogRecord
+}
+
+// WithDialOptions appends extra grpc.DialOptions used to dial the OTLP
+// endpoint, applied after NewFlusher's own defaults so they can override
+// them - e.g. pass grpc.WithTransportCredentials with a real TLS config
+// instead of the insecure default
+func WithDialOptions(opts ...grpc.DialOption) FlusherOption {
+	return func(f *Flusher) { f.dialOpts = append(f.dialOpts, opts...) }
+}
+
+// NewFlusher dials the given OTLP/gRPC target and returns a Flusher ready to
+// batch and export LogRecords sharing the given resource. The connection is
+// insecure (plaintext) by default; pass WithDialOptions(grpc.WithTransportCredentials(...))
+// to dial with TLS
+func NewFlusher(ctx context.Context, target string, resource *resourcepb.Resource, opts ...FlusherOption) (*Flusher, error) {
+	f := &Flusher{
+		resource:  resource,
+		batchSize: defaultBatchSize,
+	}
+	for _, opt := range opts {
+		if opt != nil {
+			opt(f)
+		}
+	}
+
+	dialOpts := append([]grpc.DialOption{
+		grpc.WithBlock(),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	}, f.dialOpts...)
+	conn, err := grpc.DialContext(ctx, target, dialOpts...)
+	if err != nil {
+		return nil, err
+	}
+	f.conn = conn
+	f.client = collectorpb.NewLogsServiceClient(conn)
+	return f, nil
+}
+
+// Add appends rec to the current batch and flushes automatically once the
+// batch reaches the configured size
+func (f *Flusher) Add(ctx context.Context, rec *logspb.LogRecord) error {
+	f.mu.Lock()
+	f.records = append(f.records, rec)
+	full := len(f.records) >= f.batchSize
+	f.mu.Unlock()
+
+	if !full {
+		return nil
+	}
+	return f.Flush(ctx)
+}
+
+// Flush ships the current batch to the OTLP endpoint immediately,
+// regardless of how full it is. It is a no-op if the batch is empty
+func (f *Flusher) Flush(ctx context.Context) error {
+	f.mu.Lock()
+	records := f.records
+	f.records = nil
+	f.mu.Unlock()
+
+	if len(records) == 0 {
+		return nil
+	}
+
+	req := &collectorpb.ExportLogsServiceRequest{
+		ResourceLogs: []*logspb.ResourceLogs{
+			{
+				Resource:  f.resource,
+				ScopeLogs: []*logspb.ScopeLogs{{LogRecords: records}},
+			},
+		},
+	}
+
+	var callOpts []grpc.CallOption
+	if f.compression != CompressionNone {
+		callOpts = append(callOpts, grpc.UseCompressor(string(f.compression)))
+	}
+
+	_, err := f.client.Export(ctx, req, callOpts...)
+	return err
+}
+
+// Close flushes any buffered records and closes the underlying gRPC
+// connection
+func (f *Flusher) Close(ctx context.Context) error {
+	if err := f.Flush(ctx); err != nil {
+		return err
+	}
+	return f.conn.Close()
+}
@@ -0,0 +1,96 @@
+// Package otlp converts parsesyslog.LogMsg values into OpenTelemetry log
+// records, so a syslog receiver built on top of this module can ship
+// straight to any OTLP-compatible backend without hand-rolling the mapping
+package otlp
+
+import (
+	"fmt"
+
+	commonpb "go.opentelemetry.io/proto/otlp/common/v1"
+	logspb "go.opentelemetry.io/proto/otlp/logs/v1"
+	resourcepb "go.opentelemetry.io/proto/otlp/resource/v1"
+
+	"github.com/wneessen/go-syslog"
+)
+
+// severityInfo pairs the OTel SeverityNumber with the SeverityText
+// recommended for a given RFC5424 severity level
+type severityInfo struct {
+	Number logspb.SeverityNumber
+	Text   string
+}
+
+// severityMapping translates an RFC5424 severity level into the OTel
+// SeverityNumber/SeverityText pair recommended by the OpenTelemetry logs
+// data model appendix for syslog sources
+// See: https://opentelemetry.io/docs/specs/otel/logs/data-model-appendix/#appendix-b-severitynumber-example-mappings
+var severityMapping = map[parsesyslog.Severity]severityInfo{
+	parsesyslog.SeverityDebug:         {logspb.SeverityNumber_SEVERITY_NUMBER_DEBUG, "DEBUG"},
+	parsesyslog.SeverityInformational: {logspb.SeverityNumber_SEVERITY_NUMBER_INFO, "INFO"},
+	parsesyslog.SeverityNotice:        {logspb.SeverityNumber_SEVERITY_NUMBER_INFO2, "NOTICE"},
+	parsesyslog.SeverityWarning:       {logspb.SeverityNumber_SEVERITY_NUMBER_WARN, "WARN"},
+	parsesyslog.SeverityError:         {logspb.SeverityNumber_SEVERITY_NUMBER_ERROR, "ERROR"},
+	parsesyslog.SeverityCritical:      {logspb.SeverityNumber_SEVERITY_NUMBER_ERROR2, "CRITICAL"},
+	parsesyslog.SeverityAlert:         {logspb.SeverityNumber_SEVERITY_NUMBER_ERROR3, "ALERT"},
+	parsesyslog.SeverityEmergency:     {logspb.SeverityNumber_SEVERITY_NUMBER_FATAL, "EMERGENCY"},
+}
+
+// Convert maps a parsesyslog.LogMsg into an OTLP LogRecord. Hostname,
+// AppName, ProcID, MsgID and Facility are attached as log attributes using
+// the conventions host.name, service.name, process.pid, log.record.uid and
+// syslog.facility, and each StructuredDataElement is flattened into
+// attributes keyed syslog.sd.<ID>.<param>
+func Convert(lm parsesyslog.LogMsg) *logspb.LogRecord {
+	rec := &logspb.LogRecord{
+		Body: stringValue(lm.Message.String()),
+	}
+	if !lm.Timestamp.IsZero() {
+		rec.TimeUnixNano = uint64(lm.Timestamp.UnixNano())
+	}
+
+	if sev, ok := severityMapping[lm.Severity]; ok {
+		rec.SeverityNumber = sev.Number
+		rec.SeverityText = sev.Text
+	}
+
+	addStringAttr(rec, "host.name", lm.Hostname)
+	addStringAttr(rec, "service.name", lm.AppName)
+	addStringAttr(rec, "process.pid", lm.ProcID)
+	addStringAttr(rec, "log.record.uid", lm.MsgID)
+	addStringAttr(rec, "syslog.facility", fmt.Sprintf("%d", lm.Facility))
+
+	for _, sd := range lm.StructuredData {
+		for _, p := range sd.Param {
+			addStringAttr(rec, fmt.Sprintf("syslog.sd.%s.%s", sd.ID, p.Name), p.Value)
+		}
+	}
+
+	return rec
+}
+
+// Resource returns the OTLP resource carrying the host.name/service.name
+// attributes shared by every record parsed off the same connection
+func Resource(lm parsesyslog.LogMsg) *resourcepb.Resource {
+	res := &resourcepb.Resource{}
+	addResourceAttr(res, "host.name", lm.Hostname)
+	addResourceAttr(res, "service.name", lm.AppName)
+	return res
+}
+
+func stringValue(s string) *commonpb.AnyValue {
+	return &commonpb.AnyValue{Value: &commonpb.AnyValue_StringValue{StringValue: s}}
+}
+
+func addStringAttr(rec *logspb.LogRecord, key, value string) {
+	if value == "" {
+		return
+	}
+	rec.Attributes = append(rec.Attributes, &commonpb.KeyValue{Key: key, Value: stringValue(value)})
+}
+
+func addResourceAttr(res *resourcepb.Resource, key, value string) {
+	if value == "" {
+		return
+	}
+	res.Attributes = append(res.Attributes, &commonpb.KeyValue{Key: key, Value: stringValue(value)})
+}
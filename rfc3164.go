@@ -0,0 +1,384 @@
+package parsesyslog
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"io"
+	"net"
+	"strings"
+	"time"
+)
+
+// ErrInvalidHostname is returned by the RFC3164 parser when WithStrictHostname
+// is enabled and the HOSTNAME field is neither a valid RFC952/1123 hostname
+// nor a valid IP address
+var ErrInvalidHostname = errors.New("rfc3164: hostname does not conform to RFC952/1123 and is not a valid IP")
+
+// defaultRFC3164TimestampFormats holds the prioritized list of timestamp
+// layouts the RFC3164 parser tries by default. The first layout that parses
+// the accumulated timestamp token wins.
+var defaultRFC3164TimestampFormats = []string{
+	time.RFC3339,
+	"Jan 02 15:04:05 2006",
+	"Jan _2 15:04:05 2006",
+	"Jan 02 15:04:05",
+	"Jan _2 15:04:05",
+}
+
+// RFC3164Msg represents a log message that matches RFC3164 (the "BSD
+// syslog protocol")
+// See: https://datatracker.ietf.org/doc/html/rfc3164
+type RFC3164Msg struct {
+	buf     bytes.Buffer
+	options rfc3164Options
+}
+
+// rfc3164Options holds the configuration applied via RFC3164Option
+type rfc3164Options struct {
+	currentYear      bool
+	strictHostname   bool
+	timestampFormats []string
+}
+
+// RFC3164Option is a functional option to configure a RFC3164Msg parser
+type RFC3164Option func(*rfc3164Options)
+
+// WithCurrentYear makes the parser fill in the current year on timestamps
+// whose layout (e.g. "Jan 02 15:04:05") does not carry a year of its own,
+// since RFC3164 timestamps omit the year entirely
+func WithCurrentYear() RFC3164Option {
+	return func(o *rfc3164Options) {
+		o.currentYear = true
+	}
+}
+
+// WithStrictHostname rejects HOSTNAME values that contain characters outside
+// the RFC952/1123 hostname charset and that are not a valid IP address,
+// returning ErrInvalidHostname instead of accepting them as-is
+func WithStrictHostname() RFC3164Option {
+	return func(o *rfc3164Options) {
+		o.strictHostname = true
+	}
+}
+
+// WithTimestampFormats appends additional timestamp layouts that are tried,
+// in order, after the built-in prioritized list has been exhausted
+func WithTimestampFormats(formats []string) RFC3164Option {
+	return func(o *rfc3164Options) {
+		o.timestampFormats = append(o.timestampFormats, formats...)
+	}
+}
+
+// NewRFC3164Msg returns a new RFC3164Msg parser configured with the given
+// options
+func NewRFC3164Msg(opts ...RFC3164Option) *RFC3164Msg {
+	m := &RFC3164Msg{
+		options: rfc3164Options{
+			timestampFormats: append([]string{}, defaultRFC3164TimestampFormats...),
+		},
+	}
+	for _, opt := range opts {
+		if opt == nil {
+			continue
+		}
+		opt(&m.options)
+	}
+	return m
+}
+
+// parseReader is the parser function that is able to interpret RFC3164 and
+// satisfies the Parser interface
+func (m *RFC3164Msg) parseReader(r io.Reader) (LogMsg, error) {
+	l := LogMsg{
+		Type: RFC3164,
+	}
+
+	br, ok := r.(*bufio.Reader)
+	if !ok {
+		br = bufio.NewReader(r)
+	}
+
+	return m.parse(br, l)
+}
+
+// ParseReaderWithFraming behaves like ParseReader but first bounds a single
+// message within r using the given RFC6587 framing strategy, which is
+// useful when RFC3164 messages are carried over a TCP stream rather than
+// delivered one per UDP datagram
+func (m *RFC3164Msg) ParseReaderWithFraming(r io.Reader, framing Framing) (LogMsg, error) {
+	l := LogMsg{
+		Type: RFC3164,
+	}
+
+	br, ok := r.(*bufio.Reader)
+	if !ok {
+		br = bufio.NewReader(r)
+	}
+	fr, err := framing.Frame(br)
+	if err != nil {
+		return l, err
+	}
+	// Drain whatever parse() leaves behind: with OctetCountingFraming, fr is
+	// a LimitReader over the shared br, and returning early would otherwise
+	// leave its unread remainder in front of the next message's length prefix
+	defer func() { _, _ = io.Copy(io.Discard, fr) }()
+
+	return m.parse(bufio.NewReader(fr), l)
+}
+
+// parse runs the RFC3164 header and message parsing against a reader that is
+// already bounded to exactly one message
+func (m *RFC3164Msg) parse(br *bufio.Reader, l LogMsg) (LogMsg, error) {
+	if err := m.parsePriority(br, &l); err != nil {
+		return l, err
+	}
+	if err := m.parseTimestamp(br, &l); err != nil {
+		return l, err
+	}
+	if err := m.parseHostname(br, &l); err != nil {
+		return l, err
+	}
+	if err := m.parseTag(br, &l); err != nil {
+		return l, err
+	}
+
+	md, err := io.ReadAll(br)
+	if err != nil {
+		return l, err
+	}
+	l.Message.Write(md)
+	l.MsgLength = l.Message.Len()
+
+	return l, nil
+}
+
+// parsePriority will try to parse the priority part of the RFC3164 header.
+// Unlike RFC5424, RFC3164 does not bound the PRI value explicitly, so we cap
+// it at 3 digits before demanding the closing '>'
+// See: https://datatracker.ietf.org/doc/html/rfc3164#section-4.1.1
+func (m *RFC3164Msg) parsePriority(r *bufio.Reader, lm *LogMsg) error {
+	b, err := r.ReadByte()
+	if err != nil {
+		return err
+	}
+	if b != '<' {
+		return ErrWrongFormat
+	}
+
+	var ps []byte
+	for i := 0; i < 4; i++ {
+		b, err = r.ReadByte()
+		if err != nil {
+			return err
+		}
+		if b == '>' {
+			break
+		}
+		if i == 3 {
+			return ErrInvalidPrio
+		}
+		ps = append(ps, b)
+	}
+	p, err := atoi(ps)
+	if err != nil {
+		return ErrInvalidPrio
+	}
+	lm.Priority = Priority(p)
+	lm.Facility = FacilityFromPrio(lm.Priority)
+	lm.Severity = SeverityFromPrio(lm.Priority)
+	return nil
+}
+
+// parseTimestamp will try to parse the timestamp part of the RFC3164 header.
+// Some senders (and callers of WithTimestampFormats) use a single
+// space-free token such as time.RFC3339 instead of the traditional BSD
+// form, so the first token is tried against every configured layout before
+// committing to the 3-token form. The base "Mmm dd hh:mm:ss" timestamp is
+// assembled from 3 space-separated tokens, keeping the doubled space that
+// a space-padded single-digit day (the "_2" layouts) produces without
+// counting the empty field as a token. Some senders append a non-conforming year after that; since
+// we cannot tell in advance whether one follows, we peek it and only
+// consume it from r if appending it turns the timestamp into a match -
+// otherwise it is left untouched for parseHostname to read
+// See: https://datatracker.ietf.org/doc/html/rfc3164#section-4.1.2
+func (m *RFC3164Msg) parseTimestamp(r *bufio.Reader, lm *LogMsg) error {
+	m.buf.Reset()
+
+	tok, _, err := readBytesUntilSpace(r)
+	if err != nil {
+		return err
+	}
+	m.buf.Write(tok)
+
+	if ts, ok := m.tryParseTimestamp(m.buf.String()); ok {
+		lm.Timestamp = ts
+		return nil
+	}
+
+	for tokens := 1; tokens < 3; {
+		tok, _, err := readBytesUntilSpace(r)
+		if err != nil {
+			return err
+		}
+		m.buf.WriteByte(' ')
+		if len(tok) == 0 {
+			// a doubled space, e.g. the padding before a single-digit day;
+			// keep the extra separator but don't count it as a token
+			continue
+		}
+		m.buf.Write(tok)
+		tokens++
+	}
+
+	ts, ok := m.tryParseTimestamp(m.buf.String())
+
+	if year, yok := m.peekYear(r); yok {
+		if ts2, ok2 := m.tryParseTimestamp(m.buf.String() + " " + year); ok2 {
+			if _, _, err := readBytesUntilSpace(r); err != nil {
+				return err
+			}
+			lm.Timestamp = ts2
+			return nil
+		}
+	}
+
+	if !ok {
+		return ErrInvalidTimestamp
+	}
+	lm.Timestamp = ts
+	return nil
+}
+
+// peekYear reports whether the next field in r looks like a 4-digit year
+// (e.g. "2003 ") without consuming it, so parseTimestamp can decide whether
+// to fold it into the timestamp or leave it for the next field
+func (m *RFC3164Msg) peekYear(r *bufio.Reader) (string, bool) {
+	b, err := r.Peek(5)
+	if err != nil || len(b) < 5 || b[4] != ' ' {
+		return "", false
+	}
+	for i := 0; i < 4; i++ {
+		if b[i] < '0' || b[i] > '9' {
+			return "", false
+		}
+	}
+	return string(b[:4]), true
+}
+
+// tryParseTimestamp tries every configured layout against s and, if
+// WithCurrentYear is set, backfills the year on layouts that do not carry one
+func (m *RFC3164Msg) tryParseTimestamp(s string) (time.Time, bool) {
+	for _, layout := range m.options.timestampFormats {
+		ts, err := time.Parse(layout, s)
+		if err != nil {
+			continue
+		}
+		if m.options.currentYear && !strings.Contains(layout, "2006") {
+			ts = time.Date(time.Now().Year(), ts.Month(), ts.Day(), ts.Hour(),
+				ts.Minute(), ts.Second(), ts.Nanosecond(), ts.Location())
+		}
+		return ts, true
+	}
+	return time.Time{}, false
+}
+
+// parseHostname will try to read the HOSTNAME part of the RFC3164 header. If
+// WithStrictHostname is enabled, values that are neither a valid
+// RFC952/1123 hostname nor a valid IP address are rejected
+// See: https://datatracker.ietf.org/doc/html/rfc3164#section-4.1.2
+func (m *RFC3164Msg) parseHostname(r *bufio.Reader, lm *LogMsg) error {
+	tok, _, err := readBytesUntilSpace(r)
+	if err != nil {
+		return err
+	}
+	hostname := string(tok)
+	if m.options.strictHostname && !isValidHostname(hostname) {
+		return ErrInvalidHostname
+	}
+	lm.Hostname = hostname
+	return nil
+}
+
+// parseTag will try to read the "TAG[PID]:" convention that precedes the
+// free-form message text and split it into AppName and ProcID. Not every
+// sender follows this convention, so a missing ':' before the next space is
+// not treated as an error - the tag is simply left empty
+// See: https://datatracker.ietf.org/doc/html/rfc3164#section-4.1.3
+func (m *RFC3164Msg) parseTag(r *bufio.Reader, lm *LogMsg) error {
+	m.buf.Reset()
+	for {
+		b, err := r.ReadByte()
+		if err != nil {
+			return err
+		}
+		if b == ':' {
+			break
+		}
+		if b == ' ' {
+			// no tag after all: keep the word read so far as message text
+			lm.Message.Write(m.buf.Bytes())
+			return r.UnreadByte()
+		}
+		m.buf.WriteByte(b)
+	}
+
+	tag := m.buf.String()
+	m.buf.Reset()
+	if idx := strings.IndexByte(tag, '['); idx >= 0 && strings.HasSuffix(tag, "]") {
+		lm.AppName = tag[:idx]
+		lm.ProcID = tag[idx+1 : len(tag)-1]
+	} else {
+		lm.AppName = tag
+	}
+
+	if b, err := r.ReadByte(); err == nil && b != ' ' {
+		return r.UnreadByte()
+	}
+	return nil
+}
+
+// isValidHostname reports whether h is either a valid IP address or a valid
+// RFC952/1123 hostname (dot-separated labels of letters, digits and hyphens,
+// neither leading nor trailing with a hyphen)
+func isValidHostname(h string) bool {
+	if h == "" {
+		return false
+	}
+	if net.ParseIP(h) != nil {
+		return true
+	}
+	for _, label := range strings.Split(h, ".") {
+		if !isValidHostnameLabel(label) {
+			return false
+		}
+	}
+	return true
+}
+
+// isValidHostnameLabel reports whether l is a valid single hostname label
+func isValidHostnameLabel(l string) bool {
+	if l == "" || len(l) > 63 {
+		return false
+	}
+	if l[0] == '-' || l[len(l)-1] == '-' {
+		return false
+	}
+	for _, c := range l {
+		switch {
+		case c >= 'a' && c <= 'z':
+		case c >= 'A' && c <= 'Z':
+		case c >= '0' && c <= '9':
+		case c == '-':
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+func init() {
+	RegisterParserType(RFC3164, func() Parser {
+		return NewRFC3164Msg()
+	})
+}
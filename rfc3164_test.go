@@ -0,0 +1,87 @@
+package parsesyslog
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestRFC3164ParseTimestamp(t *testing.T) {
+	tests := []struct {
+		name string
+		opts []RFC3164Option
+		msg  string
+		want time.Time
+	}{
+		{
+			name: "single-token RFC3339 layout",
+			msg:  "<34>2003-10-11T22:14:15Z mymachine su: hi",
+			want: time.Date(2003, time.October, 11, 22, 14, 15, 0, time.UTC),
+		},
+		{
+			name: "BSD form without year",
+			opts: []RFC3164Option{WithCurrentYear()},
+			msg:  "<34>Oct 11 22:14:15 mymachine su: hi",
+			want: time.Date(time.Now().Year(), time.October, 11, 22, 14, 15, 0, time.UTC),
+		},
+		{
+			name: "BSD form with non-conforming trailing year",
+			msg:  "<34>Oct 11 22:14:15 2003 mymachine su: hi",
+			want: time.Date(2003, time.October, 11, 22, 14, 15, 0, time.UTC),
+		},
+		{
+			name: "space-padded single-digit day",
+			msg:  "<34>Oct  1 22:14:15 2003 mymachine su: hi",
+			want: time.Date(2003, time.October, 1, 22, 14, 15, 0, time.UTC),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := NewRFC3164Msg(tt.opts...)
+			lm, err := m.parseReader(bytes.NewReader([]byte(tt.msg)))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !lm.Timestamp.Equal(tt.want) {
+				t.Fatalf("Timestamp = %v, want %v", lm.Timestamp, tt.want)
+			}
+			if lm.Hostname != "mymachine" {
+				t.Fatalf("Hostname = %q, want %q", lm.Hostname, "mymachine")
+			}
+		})
+	}
+}
+
+func TestRFC3164ParseHostnameStrict(t *testing.T) {
+	tests := []struct {
+		name    string
+		msg     string
+		wantErr error
+	}{
+		{
+			name: "valid hostname",
+			msg:  "<34>Oct 11 22:14:15 2003 mymachine.example.com su: hi",
+		},
+		{
+			name: "valid IP address",
+			msg:  "<34>Oct 11 22:14:15 2003 192.0.2.1 su: hi",
+		},
+		{
+			name:    "invalid hostname",
+			msg:     "<34>Oct 11 22:14:15 2003 not_a_valid-host! su: hi",
+			wantErr: ErrInvalidHostname,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := NewRFC3164Msg(WithStrictHostname())
+			_, err := m.parseReader(bytes.NewReader([]byte(tt.msg)))
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("got error %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
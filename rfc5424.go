@@ -4,63 +4,132 @@ import (
 	"bufio"
 	"bytes"
 	"errors"
+	"fmt"
 	"io"
 	"time"
 )
 
 // RFC5424Msg represents a log message in that matches RFC5424
 type RFC5424Msg struct {
-	buf bytes.Buffer
+	buf     bytes.Buffer
+	options rfc5424Options
+}
+
+// rfc5424Options holds the configuration applied via RFC5424Option
+type rfc5424Options struct {
+	lenient bool
+}
+
+// RFC5424Option is a functional option for configuring an RFC5424Msg parser
+type RFC5424Option func(*rfc5424Options)
+
+// WithLenientStructuredData restores the permissive, pre-hardening
+// structured-data parsing behavior: no escape handling, no SD-NAME charset
+// enforcement and no typed SDParseError, for callers that depended on it
+// accepting malformed input
+func WithLenientStructuredData() RFC5424Option {
+	return func(o *rfc5424Options) {
+		o.lenient = true
+	}
+}
+
+// NewRFC5424Msg returns a new RFC5424Msg parser configured with the given
+// options
+func NewRFC5424Msg(opts ...RFC5424Option) *RFC5424Msg {
+	m := &RFC5424Msg{}
+	for _, opt := range opts {
+		if opt != nil {
+			opt(&m.options)
+		}
+	}
+	return m
 }
 
 // ParseReader is the parser function that is able to interpret RFC5424 and
-// satisfies the Parser interface
+// satisfies the Parser interface. It frames messages using
+// OctetCountingFraming, which is what RFC5424 transports have historically
+// used on the wire
 func (m *RFC5424Msg) parseReader(r io.Reader) (LogMsg, error) {
+	return m.ParseReaderWithFraming(r, OctetCountingFraming{})
+}
+
+// ParseReaderWithFraming behaves like ParseReader but lets the caller pick
+// the RFC6587 framing strategy used to bound a single message within r, e.g.
+// NonTransparentFraming for LF-delimited TCP streams
+func (m *RFC5424Msg) ParseReaderWithFraming(r io.Reader, framing Framing) (LogMsg, error) {
 	l := LogMsg{
 		Type: RFC5424,
 	}
+	err := m.parseInto(r, framing, &l)
+	return l, err
+}
+
+// ParseReaderPooled behaves like ParseReaderWithFraming, but parses into a
+// *LogMsg obtained from AcquireLogMsg instead of a fresh value, so its
+// StructuredData backing array (and that of each element's Param) is reused
+// across messages rather than discarded on every parse. Callers must call
+// Release on the returned LogMsg once done with it
+func (m *RFC5424Msg) ParseReaderPooled(r io.Reader, framing Framing) (*LogMsg, error) {
+	lm := AcquireLogMsg()
+	if err := m.parseInto(r, framing, lm); err != nil {
+		lm.Release()
+		return nil, err
+	}
+	return lm, nil
+}
+
+// parseInto parses a single RFC5424 message from r into lm, reusing
+// whatever StructuredData backing array lm already has instead of
+// discarding it - this is what lets a pooled *LogMsg obtained via
+// AcquireLogMsg reuse its StructuredData storage across repeated parses.
+// Each parse still allocates its own bufio.Reader and field strings
+func (m *RFC5424Msg) parseInto(r io.Reader, framing Framing, lm *LogMsg) error {
+	lm.Type = RFC5424
 
 	br, ok := r.(*bufio.Reader)
 	if !ok {
 		br = bufio.NewReader(r)
 	}
-	ml, err := readMsgLength(br)
+	fr, err := framing.Frame(br)
 	if err != nil {
-		return l, err
+		return err
 	}
+	// Whatever happens below, drain the rest of this frame before returning:
+	// with OctetCountingFraming, fr is a LimitReader over the shared br, and
+	// an error return partway through a message would otherwise leave its
+	// unread remainder in front of the next message's length prefix
+	defer func() { _, _ = io.Copy(io.Discard, fr) }()
 
-	lr := io.LimitReader(br, int64(ml))
-	br = bufio.NewReaderSize(lr, ml)
-	if err := m.parseHeader(br, &l); err != nil {
+	br = bufio.NewReader(fr)
+	if err := m.parseHeader(br, lm); err != nil {
 		switch {
 		case errors.Is(err, io.EOF):
-			return l, ErrPrematureEOF
+			return ErrPrematureEOF
 		default:
-			return l, err
+			return err
 		}
 	}
-	if err := m.parseStructuredData(br, &l); err != nil {
+	if err := m.parseStructuredData(br, lm); err != nil {
 		switch {
 		case errors.Is(err, io.EOF):
-			return l, ErrPrematureEOF
+			return ErrPrematureEOF
 		default:
-			return l, err
+			return err
 		}
 	}
 
-	if err := m.parseBOM(br, &l); err != nil {
-		return l, nil
+	if err := m.parseBOM(br, lm); err != nil {
+		return nil
 	}
 
-	//rb := make([]byte, ml - l.Message.Len())
 	md, err := io.ReadAll(br)
 	if err != nil {
-		return l, err
+		return err
 	}
-	l.Message.Write(md)
-	l.MsgLength = l.Message.Len()
+	lm.Message.Write(md)
+	lm.MsgLength = lm.Message.Len()
 
-	return l, nil
+	return nil
 }
 
 // parseHeader will try to parse the header of a RFC5424 syslog message and store
@@ -92,12 +161,238 @@ func (m *RFC5424Msg) parseHeader(r *bufio.Reader, lm *LogMsg) error {
 	return nil
 }
 
-// parseStructuredData will try to parse the SD of a RFC5424 syslog message and
-// store it in the provided LogMsg pointer
-// See: https://datatracker.ietf.org/doc/html/rfc5424#section-6.2
-// We are using a simple finite state machine here to parse through the different
-// states of the parameters and elements
+// sdState enumerates the states of the structured-data state machine, named
+// after the RFC5424 §6.3 ABNF productions they parse
+type sdState int
+
+const (
+	sdStateSDID sdState = iota
+	sdStateSDName
+	sdStatePreEQ
+	sdStatePreQuote
+	sdStateSDValue
+	sdStatePostValue
+)
+
+// SDParseError reports a structured-data parsing failure together with the
+// byte offset, relative to the start of the SD-ELEMENT list, at which it was
+// detected, so a caller can log or resync past it
+type SDParseError struct {
+	Err    error
+	Offset int
+}
+
+func (e *SDParseError) Error() string {
+	return fmt.Sprintf("%s (offset %d)", e.Err, e.Offset)
+}
+
+func (e *SDParseError) Unwrap() error {
+	return e.Err
+}
+
+var (
+	// ErrSDBadName is wrapped in a SDParseError when an SD-ID or PARAM-NAME
+	// contains a byte outside the RFC5424 §6.3.2 SD-NAME charset (printable
+	// ASCII excluding '=', ']', '"' and SP)
+	ErrSDBadName = errors.New("rfc5424: invalid SD-NAME")
+	// ErrSDUnterminatedValue is wrapped in a SDParseError when a quoted
+	// PARAM-VALUE, or a backslash escape inside it, is not closed before
+	// the structured data runs out of input
+	ErrSDUnterminatedValue = errors.New("rfc5424: unterminated SD param value")
+	// ErrSDBadEscape is wrapped in a SDParseError when a backslash inside a
+	// quoted PARAM-VALUE is not followed by '\\', '"' or ']'
+	ErrSDBadEscape = errors.New("rfc5424: invalid escape sequence in SD param value")
+)
+
+// isValidSDNameByte reports whether b may appear in an SD-NAME (used for
+// both SD-ID and PARAM-NAME), per RFC5424 §6.3.2: printable US-ASCII except
+// '=', ']', '"' and SP
+func isValidSDNameByte(b byte) bool {
+	if b < 0x21 || b > 0x7E {
+		return false
+	}
+	switch b {
+	case '=', ']', '"':
+		return false
+	default:
+		return true
+	}
+}
+
+// parseStructuredData will try to parse the SD of a RFC5424 syslog message
+// and store it in the provided LogMsg pointer. By default it runs a strict
+// state machine honoring RFC5424 §6.3.3 escaping; WithLenientStructuredData
+// restores the permissive pre-hardening behavior for callers that depend on
+// it
+// See: https://datatracker.ietf.org/doc/html/rfc5424#section-6.3
 func (m *RFC5424Msg) parseStructuredData(r *bufio.Reader, lm *LogMsg) error {
+	if m.options.lenient {
+		return m.parseStructuredDataLenient(r, lm)
+	}
+	return m.parseStructuredDataStrict(r, lm)
+}
+
+// parseStructuredDataStrict implements the RFC5424 §6.3 structured-data
+// grammar as an explicit state machine: SD-ID, SD-NAME, pre-EQ, pre-QUOTE,
+// SD-VALUE and post-VALUE
+func (m *RFC5424Msg) parseStructuredDataStrict(r *bufio.Reader, lm *LogMsg) error {
+	m.buf.Reset()
+
+	offset := 0
+	nb, err := r.ReadByte()
+	if err != nil {
+		return err
+	}
+	offset++
+	if nb == '-' {
+		if _, err := r.ReadByte(); err != nil {
+			return err
+		}
+		return nil
+	}
+	if nb != '[' {
+		return ErrWrongSDFormat
+	}
+
+	sds := lm.StructuredData[:0]
+	sd := newPooledSDElement()
+	var sdp StructuredDataParam
+	state := sdStateSDID
+
+	closeElement := func() (done bool, err error) {
+		sds = append(sds, sd)
+		sd = newPooledSDElement()
+		nxt, rerr := r.ReadByte()
+		if rerr != nil {
+			return false, &SDParseError{Err: ErrWrongSDFormat, Offset: offset}
+		}
+		offset++
+		switch nxt {
+		case '[':
+			state = sdStateSDID
+			return false, nil
+		case ' ':
+			lm.StructuredData = sds
+			return true, nil
+		default:
+			return false, &SDParseError{Err: ErrWrongSDFormat, Offset: offset}
+		}
+	}
+
+	for {
+		b, err := r.ReadByte()
+		if err != nil {
+			if state == sdStateSDValue {
+				return &SDParseError{Err: ErrSDUnterminatedValue, Offset: offset}
+			}
+			return err
+		}
+		offset++
+
+		switch state {
+		case sdStateSDID:
+			switch {
+			case b == ' ':
+				sd.ID = m.buf.String()
+				m.buf.Reset()
+				state = sdStateSDName
+			case b == ']':
+				// a param-less element, e.g. "[exampleSDID]"
+				sd.ID = m.buf.String()
+				m.buf.Reset()
+				done, err := closeElement()
+				if err != nil {
+					return err
+				}
+				if done {
+					return nil
+				}
+			case isValidSDNameByte(b):
+				m.buf.WriteByte(b)
+			default:
+				return &SDParseError{Err: ErrSDBadName, Offset: offset}
+			}
+		case sdStateSDName:
+			switch {
+			case b == ' ':
+				continue
+			case b == ']':
+				done, err := closeElement()
+				if err != nil {
+					return err
+				}
+				if done {
+					return nil
+				}
+			case isValidSDNameByte(b):
+				m.buf.WriteByte(b)
+				state = sdStatePreEQ
+			default:
+				return &SDParseError{Err: ErrSDBadName, Offset: offset}
+			}
+		case sdStatePreEQ:
+			switch {
+			case b == '=':
+				sdp.Name = m.buf.String()
+				m.buf.Reset()
+				state = sdStatePreQuote
+			case isValidSDNameByte(b):
+				m.buf.WriteByte(b)
+			default:
+				return &SDParseError{Err: ErrSDBadName, Offset: offset}
+			}
+		case sdStatePreQuote:
+			if b != '"' {
+				return &SDParseError{Err: ErrWrongSDFormat, Offset: offset}
+			}
+			state = sdStateSDValue
+		case sdStateSDValue:
+			switch b {
+			case '\\':
+				eb, eerr := r.ReadByte()
+				if eerr != nil {
+					return &SDParseError{Err: ErrSDUnterminatedValue, Offset: offset}
+				}
+				offset++
+				switch eb {
+				case '\\', '"', ']':
+					m.buf.WriteByte(eb)
+				default:
+					return &SDParseError{Err: ErrSDBadEscape, Offset: offset}
+				}
+			case '"':
+				sdp.Value = m.buf.String()
+				m.buf.Reset()
+				sd.Param = append(sd.Param, sdp)
+				sdp = StructuredDataParam{}
+				state = sdStatePostValue
+			default:
+				m.buf.WriteByte(b)
+			}
+		case sdStatePostValue:
+			switch b {
+			case ' ':
+				state = sdStateSDName
+			case ']':
+				done, err := closeElement()
+				if err != nil {
+					return err
+				}
+				if done {
+					return nil
+				}
+			default:
+				return &SDParseError{Err: ErrWrongSDFormat, Offset: offset}
+			}
+		}
+	}
+}
+
+// parseStructuredDataLenient is the original, permissive structured-data
+// parser, kept available via WithLenientStructuredData for callers that
+// relied on its tolerance of malformed input before parseStructuredDataStrict
+// replaced it as the default
+func (m *RFC5424Msg) parseStructuredDataLenient(r *bufio.Reader, lm *LogMsg) error {
 	m.buf.Reset()
 
 	nb, err := r.ReadByte()
@@ -115,8 +410,8 @@ func (m *RFC5424Msg) parseStructuredData(r *bufio.Reader, lm *LogMsg) error {
 		return ErrWrongSDFormat
 	}
 
-	var sds []StructuredDataElement
-	var sd StructuredDataElement
+	sds := lm.StructuredData[:0]
+	sd := newPooledSDElement()
 	var sdp StructuredDataParam
 	insideelem := true
 	insideparam := false
@@ -129,7 +424,7 @@ func (m *RFC5424Msg) parseStructuredData(r *bufio.Reader, lm *LogMsg) error {
 		if b == ']' {
 			insideelem = false
 			sds = append(sds, sd)
-			sd = StructuredDataElement{}
+			sd = newPooledSDElement()
 			m.buf.Reset()
 			continue
 		}
@@ -0,0 +1,42 @@
+package parsesyslog
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+)
+
+// rfc5424BenchMsg is a realistic RFC5424 message with a three-param
+// structured-data element, framed with an octet-counting prefix
+var rfc5424BenchMsg = func() []byte {
+	msg := []byte(`<34>1 2003-10-11T22:14:15.003Z mymachine.example.com su - ID47 ` +
+		`[exampleSDID@32473 iut="3" eventSource="Application" eventID="1011"] ` +
+		`An application event log entry`)
+	return append([]byte(fmt.Sprintf("%d ", len(msg))), msg...)
+}()
+
+// BenchmarkRFC5424Parse compares the allocating by-value parse path against
+// the pooled path across a realistic RFC5424 message
+func BenchmarkRFC5424Parse(b *testing.B) {
+	b.Run("ByValue", func(b *testing.B) {
+		m := &RFC5424Msg{}
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			if _, err := m.ParseReaderWithFraming(bytes.NewReader(rfc5424BenchMsg), OctetCountingFraming{}); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+
+	b.Run("Pooled", func(b *testing.B) {
+		m := &RFC5424Msg{}
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			lm, err := m.ParseReaderPooled(bytes.NewReader(rfc5424BenchMsg), OctetCountingFraming{})
+			if err != nil {
+				b.Fatal(err)
+			}
+			lm.Release()
+		}
+	})
+}
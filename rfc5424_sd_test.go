@@ -0,0 +1,93 @@
+package parsesyslog
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"testing"
+)
+
+// frameRFC5424 wraps a raw RFC5424 message with an octet-counting prefix, as
+// rfc5424BenchMsg does, so the table tests below can exercise the full
+// parseHeader/parseStructuredData path through ParseReaderWithFraming
+func frameRFC5424(msg string) []byte {
+	b := []byte(msg)
+	return append([]byte(fmt.Sprintf("%d ", len(b))), b...)
+}
+
+func TestRFC5424ParseStructuredDataStrict(t *testing.T) {
+	tests := []struct {
+		name    string
+		msg     string
+		wantErr error
+		check   func(t *testing.T, lm LogMsg)
+	}{
+		{
+			name: "param-less element",
+			msg:  `<34>1 2003-10-11T22:14:15.003Z mymachine.example.com su - ID47 [exampleSDID] An application event log entry`,
+			check: func(t *testing.T, lm LogMsg) {
+				if len(lm.StructuredData) != 1 || lm.StructuredData[0].ID != "exampleSDID" {
+					t.Fatalf("unexpected StructuredData: %+v", lm.StructuredData)
+				}
+				if len(lm.StructuredData[0].Param) != 0 {
+					t.Fatalf("expected no params, got %+v", lm.StructuredData[0].Param)
+				}
+			},
+		},
+		{
+			name: "escaped characters in value",
+			msg:  `<34>1 2003-10-11T22:14:15.003Z mymachine.example.com su - ID47 [exampleSDID@32473 path="C:\\tmp\\file" note="a \"quoted\" thing" range="[0\]"] hi`,
+			check: func(t *testing.T, lm LogMsg) {
+				if len(lm.StructuredData) != 1 {
+					t.Fatalf("unexpected StructuredData: %+v", lm.StructuredData)
+				}
+				want := map[string]string{
+					"path":  `C:\tmp\file`,
+					"note":  `a "quoted" thing`,
+					"range": `[0]`,
+				}
+				got := map[string]string{}
+				for _, p := range lm.StructuredData[0].Param {
+					got[p.Name] = p.Value
+				}
+				for k, v := range want {
+					if got[k] != v {
+						t.Errorf("param %q = %q, want %q", k, got[k], v)
+					}
+				}
+			},
+		},
+		{
+			name:    "invalid SD-NAME byte",
+			msg:     `<34>1 2003-10-11T22:14:15.003Z mymachine.example.com su - ID47 [exa=mple] hi`,
+			wantErr: ErrSDBadName,
+		},
+		{
+			name:    "unterminated value",
+			msg:     `<34>1 2003-10-11T22:14:15.003Z mymachine.example.com su - ID47 [exampleSDID@32473 iut="3`,
+			wantErr: ErrSDUnterminatedValue,
+		},
+		{
+			name:    "invalid escape sequence",
+			msg:     `<34>1 2003-10-11T22:14:15.003Z mymachine.example.com su - ID47 [exampleSDID@32473 iut="\n"] hi`,
+			wantErr: ErrSDBadEscape,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := NewRFC5424Msg()
+			lm, err := m.ParseReaderWithFraming(bytes.NewReader(frameRFC5424(tt.msg)), OctetCountingFraming{})
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("got error %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			tt.check(t, lm)
+		})
+	}
+}
@@ -0,0 +1,71 @@
+package parsesyslog
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"io"
+)
+
+// ParseStream continuously parses RFC5424 messages framed with framing from
+// r, sending each successfully parsed LogMsg on out and each error on errs,
+// until ctx is cancelled or framing or parsing a message fails with an error
+// that is not recoverable. It owns the
+// underlying bufio.Reader and reuses m's scratch buffer across messages, so a
+// single RFC5424Msg can be driven over a long-lived connection without
+// the caller re-implementing the framing loop. Recoverable per-message
+// errors (currently ErrWrongFormat) are reported on errs and parsing resumes
+// at the next framing boundary instead of tearing down the stream.
+// ParseStream closes both out and errs before returning
+func (m *RFC5424Msg) ParseStream(ctx context.Context, r io.Reader, framing Framing, out chan<- LogMsg, errs chan<- error) {
+	parseStream(ctx, m.ParseReaderWithFraming, bufio.NewReader(r), framing, out, errs)
+}
+
+// ParseStream behaves like RFC5424Msg.ParseStream, but drives an RFC3164Msg
+// instead
+func (m *RFC3164Msg) ParseStream(ctx context.Context, r io.Reader, framing Framing, out chan<- LogMsg, errs chan<- error) {
+	parseStream(ctx, m.ParseReaderWithFraming, bufio.NewReader(r), framing, out, errs)
+}
+
+// parseStream implements the read-parse-emit loop shared by the per-parser
+// ParseStream methods
+func parseStream(ctx context.Context, parse func(io.Reader, Framing) (LogMsg, error), br *bufio.Reader,
+	framing Framing, out chan<- LogMsg, errs chan<- error,
+) {
+	defer close(out)
+	defer close(errs)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		default:
+		}
+
+		l, err := parse(br, framing)
+		if err != nil {
+			select {
+			case errs <- err:
+			case <-ctx.Done():
+				return
+			}
+			if isRecoverableStreamError(err) {
+				continue
+			}
+			return
+		}
+
+		select {
+		case out <- l:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// isRecoverableStreamError reports whether a per-message parse error leaves
+// the stream in a state where the next framing boundary can still be found,
+// so ParseStream can resync instead of giving up on the whole connection
+func isRecoverableStreamError(err error) bool {
+	return errors.Is(err, ErrWrongFormat)
+}